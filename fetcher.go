@@ -0,0 +1,197 @@
+package sitemap
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotModified is returned by Fetcher.Fetch when the requested URL is
+// known, through Fetcher's Cache, to still be fresh, or the server
+// confirms as much by replying 304 Not Modified to a conditional GET.
+var ErrNotModified = errors.New("sitemap: not modified")
+
+// CacheEntry holds the caching metadata a Fetcher has observed for a URL.
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	// Expires is the time, if any, before which the cached copy can be
+	// assumed fresh without contacting the server at all.
+	Expires time.Time
+}
+
+// Cache stores per-URL CacheEntry values for a Fetcher. Implementations
+// must be safe for concurrent use.
+type Cache interface {
+	Get(url string) (CacheEntry, bool)
+	Set(url string, entry CacheEntry)
+}
+
+// Fetcher downloads sitemap documents over HTTP. The zero value is ready
+// to use and fetches every URL unconditionally with http.DefaultClient,
+// matching the package-level ParseFromSite/ParseIndexFromSite functions.
+type Fetcher struct {
+	// Client is the HTTP client used to perform requests. A nil Client
+	// uses http.DefaultClient.
+	Client *http.Client
+
+	// UserAgent is sent as the User-Agent header. An empty UserAgent
+	// falls back to the package's default.
+	UserAgent string
+
+	// Retries is the number of additional attempts made after a failed
+	// request. Zero disables retries.
+	Retries int
+
+	// Backoff computes the delay before the n-th retry, n starting at 1.
+	// A nil Backoff waits n*500ms.
+	Backoff func(attempt int) time.Duration
+
+	// Cache stores ETag/Last-Modified metadata per URL so Fetch can send
+	// conditional GET headers and, when the Cache-Control/Expires
+	// headers of a previous response allow it, skip the request
+	// entirely.
+	Cache Cache
+}
+
+// Fetch downloads url, honoring any cached freshness information and
+// retrying up to Retries times after any failure (a transport error or a
+// response status of 400 or above), and returns the
+// (transparently gzip-decoded) response body. It returns ErrNotModified
+// if the Cache says the cached copy is still fresh or the server replies
+// 304 Not Modified to a conditional GET; callers should treat that as
+// "nothing to do" rather than an error.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	if f.Cache != nil {
+		if entry, ok := f.Cache.Get(url); ok && !entry.Expires.IsZero() && time.Now().Before(entry.Expires) {
+			return nil, ErrNotModified
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= f.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(f.backoff(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		body, notModified, err := f.fetchOnce(ctx, url)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if notModified {
+			return nil, ErrNotModified
+		}
+		return body, nil
+	}
+
+	return nil, lastErr
+}
+
+func (f *Fetcher) fetchOnce(ctx context.Context, url string) (body io.ReadCloser, notModified bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("User-Agent", f.userAgent())
+
+	if f.Cache != nil {
+		if entry, ok := f.Cache.Get(url); ok {
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+	}
+
+	res, err := f.client().Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		res.Body.Close()
+		return nil, true, nil
+	}
+
+	if res.StatusCode >= 400 {
+		res.Body.Close()
+		return nil, false, fmt.Errorf("sitemap error due to request %s with response status code %d", url, res.StatusCode)
+	}
+
+	if f.Cache != nil {
+		f.Cache.Set(url, CacheEntry{
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			Expires:      cacheExpiry(res.Header),
+		})
+	}
+
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(res.Body)
+		if err != nil {
+			res.Body.Close()
+			return nil, false, err
+		}
+		return readCloser{Reader: gz, closer: res.Body}, false, nil
+	}
+
+	return res.Body, false, nil
+}
+
+func (f *Fetcher) client() *http.Client {
+	if f.Client != nil {
+		return f.Client
+	}
+	return http.DefaultClient
+}
+
+func (f *Fetcher) userAgent() string {
+	if f.UserAgent != "" {
+		return f.UserAgent
+	}
+	return userAgent
+}
+
+func (f *Fetcher) backoff(attempt int) time.Duration {
+	if f.Backoff != nil {
+		return f.Backoff(attempt)
+	}
+	return time.Duration(attempt) * 500 * time.Millisecond
+}
+
+// cacheExpiry derives when a cached response can be treated as fresh
+// without revalidation, preferring Cache-Control's max-age directive over
+// Expires, per RFC 7234. It returns the zero Time if neither header is
+// present or parsable.
+func cacheExpiry(h http.Header) time.Time {
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		rest, ok := cutPrefixFold(strings.TrimSpace(directive), "max-age=")
+		if !ok {
+			continue
+		}
+		if secs, err := strconv.Atoi(rest); err == nil {
+			return time.Now().Add(time.Duration(secs) * time.Second)
+		}
+	}
+
+	if expires := h.Get("Expires"); expires != "" {
+		if t, err := http.ParseTime(expires); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
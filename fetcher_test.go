@@ -0,0 +1,89 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memCache struct {
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+func newMemCache() *memCache {
+	return &memCache{entries: make(map[string]CacheEntry)}
+}
+
+func (c *memCache) Get(url string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+func (c *memCache) Set(url string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = entry
+}
+
+func TestFetcher_ConditionalGetSkipsParsing(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`))
+	}))
+	defer srv.Close()
+
+	f := &Fetcher{Cache: newMemCache()}
+
+	body, err := f.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("first fetch failed: %s", err)
+	}
+	body.Close()
+
+	_, err = f.Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("expected ErrNotModified on second fetch, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests to the server, got %d", requests)
+	}
+}
+
+func TestFetcher_RetriesOnFailure(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`))
+	}))
+	defer srv.Close()
+
+	f := &Fetcher{Retries: 2, Backoff: func(int) time.Duration { return 0 }}
+
+	body, err := f.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("expected eventual success, got %s", err)
+	}
+	body.Close()
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
@@ -0,0 +1,87 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// elementHandler is invoked once for every element found directly under
+// the document's root element (each <url> inside a <urlset>, or each
+// <sitemap> inside a <sitemapindex>).
+type elementHandler func(d *xml.Decoder, se *xml.StartElement) error
+
+// parseLoop decodes the XML document provided by reader one token at a
+// time, looking past the root element and invoking handle for each of
+// its direct children, so memory usage stays bounded regardless of
+// document size.
+func parseLoop(reader io.Reader, handle elementHandler) error {
+	decoder := xml.NewDecoder(reader)
+
+	if err := skipToRootElement(decoder); err != nil {
+		return err
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if err := handle(decoder, &se); err != nil {
+			return err
+		}
+	}
+}
+
+// skipToRootElement advances decoder past any leading tokens (the XML
+// declaration, comments, whitespace) up to and including the document's
+// root start element.
+func skipToRootElement(decoder *xml.Decoder) error {
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			return nil
+		}
+	}
+}
+
+// entryParser handles a single direct child of a urlset document. It
+// ignores any element other than <url>.
+func entryParser(d *xml.Decoder, se *xml.StartElement, consumer EntryConsumer) error {
+	if se.Name.Local != "url" {
+		return d.Skip()
+	}
+
+	entry := newSitemapEntry()
+	if err := d.DecodeElement(entry, se); err != nil {
+		return err
+	}
+
+	return consumer(entry)
+}
+
+// indexEntryParser handles a single direct child of a sitemapindex
+// document. It ignores any element other than <sitemap>.
+func indexEntryParser(d *xml.Decoder, se *xml.StartElement, consumer IndexEntryConsumer) error {
+	if se.Name.Local != "sitemap" {
+		return d.Skip()
+	}
+
+	entry := newSitemapIndexEntry()
+	if err := d.DecodeElement(entry, se); err != nil {
+		return err
+	}
+
+	return consumer(entry)
+}
@@ -0,0 +1,184 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ctxReader wraps an io.Reader and fails reads with ctx.Err() once ctx is
+// done, so a blocked or slow Read doesn't keep a cancelled or
+// timed-out parse running.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// ParseWithContext behaves like Parse but aborts, returning ctx.Err(),
+// once ctx is done. Cancellation is checked before every read from
+// reader, so a parse of a multi-GB sitemap can be stopped promptly
+// instead of only once the consumer returns an error.
+func ParseWithContext(ctx context.Context, reader io.Reader, consumer EntryConsumer) error {
+	return Parse(ctxReader{ctx: ctx, r: reader}, consumer)
+}
+
+// ParseIndexWithContext behaves like ParseIndex but aborts, returning
+// ctx.Err(), once ctx is done.
+func ParseIndexWithContext(ctx context.Context, reader io.Reader, consumer IndexEntryConsumer) error {
+	return ParseIndex(ctxReader{ctx: ctx, r: reader}, consumer)
+}
+
+// ErrLimitExceeded is returned by ParseWith and ParseIndexWith when the
+// document holds more entries than ParseOptions.MaxEntries or more bytes
+// than ParseOptions.MaxBytes.
+var ErrLimitExceeded = errors.New("sitemap: parse limit exceeded")
+
+// ParseOptions configures ParseWith and ParseIndexWith.
+type ParseOptions struct {
+	// MaxEntries hands at most this many entries to the consumer and
+	// aborts the parse with ErrLimitExceeded when the document holds
+	// another one. A document with exactly MaxEntries entries parses
+	// without error. Zero means unlimited.
+	MaxEntries int
+
+	// MaxBytes aborts the parse with ErrLimitExceeded when the source
+	// holds more than this many bytes. A document of exactly MaxBytes
+	// bytes parses without error. Zero means unlimited.
+	MaxBytes int64
+
+	// StrictNamespace rejects, before parsing begins, any document whose
+	// first 4096 bytes don't contain the sitemap 0.9 namespace URI. It is
+	// a plain byte search: where in those bytes the URI appears is not
+	// checked, and individual entries are not checked for their namespace.
+	StrictNamespace bool
+
+	// EntryTimeout, if non-zero, bounds each call into the consumer. A
+	// consumer call that doesn't return within EntryTimeout aborts the
+	// parse with an error wrapping context.DeadlineExceeded.
+	EntryTimeout time.Duration
+}
+
+// ParseWith parses data provided by reader like Parse, but honors ctx and
+// opts: it can be cancelled mid-stream, bounded by an entry/byte count,
+// restricted to the sitemap 0.9 namespace, and protected against a slow
+// consumer.
+func ParseWith(ctx context.Context, reader io.Reader, opts ParseOptions, consumer EntryConsumer) error {
+	reader, err := applyParseOptions(reader, opts)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	return ParseWithContext(ctx, reader, func(e Entry) error {
+		if opts.MaxEntries > 0 && count >= opts.MaxEntries {
+			return ErrLimitExceeded
+		}
+		count++
+		return callWithTimeout(ctx, opts.EntryTimeout, func() error { return consumer(e) })
+	})
+}
+
+// ParseIndexWith parses a sitemap index like ParseIndex, but honors ctx
+// and opts, see ParseWith.
+func ParseIndexWith(ctx context.Context, reader io.Reader, opts ParseOptions, consumer IndexEntryConsumer) error {
+	reader, err := applyParseOptions(reader, opts)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	return ParseIndexWithContext(ctx, reader, func(e IndexEntry) error {
+		if opts.MaxEntries > 0 && count >= opts.MaxEntries {
+			return ErrLimitExceeded
+		}
+		count++
+		return callWithTimeout(ctx, opts.EntryTimeout, func() error { return consumer(e) })
+	})
+}
+
+func applyParseOptions(reader io.Reader, opts ParseOptions) (io.Reader, error) {
+	if opts.StrictNamespace {
+		br := bufio.NewReaderSize(reader, 4096)
+		peek, _ := br.Peek(4096)
+		if !bytes.Contains(peek, []byte(xmlnsSitemap)) {
+			return nil, fmt.Errorf("sitemap: StrictNamespace: document does not declare the %s namespace", xmlnsSitemap)
+		}
+		reader = br
+	}
+
+	if opts.MaxBytes > 0 {
+		reader = &limitedReader{r: reader, remaining: opts.MaxBytes}
+	}
+
+	return reader, nil
+}
+
+// limitedReader returns ErrLimitExceeded once the underlying reader turns
+// out to hold more than remaining bytes, unlike io.LimitedReader, which returns
+// io.EOF and so can't be told apart from a document that simply ended.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.remaining <= 0 {
+		// remaining hit zero exactly because the document was MaxBytes
+		// long, which is valid. Only report ErrLimitExceeded if the
+		// underlying reader actually has more to give.
+		var probe [1]byte
+		n, err := lr.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrLimitExceeded
+		}
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		return 0, ErrLimitExceeded
+	}
+	if int64(len(p)) > lr.remaining {
+		p = p[:lr.remaining]
+	}
+
+	n, err := lr.r.Read(p)
+	lr.remaining -= int64(n)
+	return n, err
+}
+
+// callWithTimeout runs fn, aborting with an error wrapping
+// context.DeadlineExceeded if it doesn't return within timeout, or with
+// ctx.Err() if ctx is done first. A timeout <= 0 disables the bound.
+func callWithTimeout(ctx context.Context, timeout time.Duration, fn func() error) error {
+	if timeout <= 0 {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("sitemap: consumer exceeded per-entry timeout of %s: %w", timeout, context.DeadlineExceeded)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
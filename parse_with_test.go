@@ -0,0 +1,86 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>https://example.com/a</loc></url>
+<url><loc>https://example.com/b</loc></url>
+</urlset>`
+
+func TestParseWithContext_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ParseWithContext(ctx, strings.NewReader(sampleSitemap), func(e Entry) error {
+		t.Fatal("consumer should not be called after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseWith_MaxEntries(t *testing.T) {
+	var count int
+	err := ParseWith(context.Background(), strings.NewReader(sampleSitemap), ParseOptions{MaxEntries: 1}, func(e Entry) error {
+		count++
+		return nil
+	})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected consumer to be called once before the limit, got %d", count)
+	}
+}
+
+func TestParseWith_StrictNamespace(t *testing.T) {
+	nonSitemap := `<?xml version="1.0"?><urlset xmlns="http://example.com/not-a-sitemap"></urlset>`
+
+	err := ParseWith(context.Background(), strings.NewReader(nonSitemap), ParseOptions{StrictNamespace: true}, func(e Entry) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("expected an error for a document outside the sitemap namespace")
+	}
+}
+
+func TestParseWith_MaxBytes(t *testing.T) {
+	var count int
+	err := ParseWith(context.Background(), strings.NewReader(sampleSitemap), ParseOptions{MaxBytes: int64(len(sampleSitemap))}, func(e Entry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Errorf("expected a document of exactly MaxBytes to parse successfully, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 entries, got %d", count)
+	}
+}
+
+func TestParseWith_MaxBytesExceeded(t *testing.T) {
+	err := ParseWith(context.Background(), strings.NewReader(sampleSitemap), ParseOptions{MaxBytes: int64(len(sampleSitemap)) - 1}, func(e Entry) error {
+		return nil
+	})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestParseWith_EntryTimeout(t *testing.T) {
+	err := ParseWith(context.Background(), strings.NewReader(sampleSitemap), ParseOptions{EntryTimeout: time.Millisecond}, func(e Entry) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected an error wrapping context.DeadlineExceeded, got %v", err)
+	}
+}
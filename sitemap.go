@@ -3,13 +3,12 @@
 package sitemap
 
 import (
-	"compress/gzip"
 	"context"
 	"encoding/xml"
-	"fmt"
+	"errors"
 	"io"
-	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -46,6 +45,12 @@ const (
 // GetPriority return priority of the page.
 // The valid value is between 0.0 and 1.0, the default value is 0.5.
 //
+// GetVideos returns the page's video:video entries, if any.
+//
+// GetAlternates returns the page's xhtml:link rel="alternate" hreflang
+// annotations as a map keyed by hreflang code. It returns nil if the page
+// declares none.
+//
 // You shouldn't implement this interface in your types.
 type Entry interface {
 	GetLocation() string
@@ -54,6 +59,8 @@ type Entry interface {
 	GetPriority() float32
 	GetImages() []Image
 	GetNews() *News
+	GetVideos() []Video
+	GetAlternates() map[string]string
 }
 
 // IndexEntry is an interface describes an element \ an URL in a sitemap index file.
@@ -109,6 +116,24 @@ func ParseFromSite(ctx context.Context, url string, consumer EntryConsumer) erro
 	return Parse(body, consumer)
 }
 
+// ParseFromSiteWithFetcher downloads sitemap from a site using fetcher,
+// parses it and for each sitemap entry calls the consumer's function. If
+// fetcher's cache reports the sitemap hasn't changed, ParseFromSiteWithFetcher
+// returns nil without parsing anything.
+func ParseFromSiteWithFetcher(ctx context.Context, url string, fetcher *Fetcher, consumer EntryConsumer) error {
+	body, err := fetcher.Fetch(ctx, url)
+	if errors.Is(err, ErrNotModified) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	defer body.Close()
+
+	return Parse(body, consumer)
+}
+
 // IndexEntryConsumer is a type represents consumer of parsed sitemaps indexes entries
 type IndexEntryConsumer func(IndexEntry) error
 
@@ -145,25 +170,35 @@ func ParseIndexFromSite(ctx context.Context, sitemapURL string, consumer IndexEn
 	return ParseIndex(body, consumer)
 }
 
-func get(ctx context.Context, url string) (io.ReadCloser, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
+// ParseIndexFromSiteWithFetcher downloads sitemap index from a site using
+// fetcher, parses it and for each sitemap index entry calls the consumer's
+// function. If fetcher's cache reports the sitemap index hasn't changed,
+// ParseIndexFromSiteWithFetcher returns nil without parsing anything.
+func ParseIndexFromSiteWithFetcher(ctx context.Context, sitemapURL string, fetcher *Fetcher, consumer IndexEntryConsumer) error {
+	body, err := fetcher.Fetch(ctx, sitemapURL)
+	if errors.Is(err, ErrNotModified) {
+		return nil
 	}
-	req.Header.Set("User-Agent", userAgent)
-
-	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if res.StatusCode >= 400 {
-		return nil, fmt.Errorf("sitemap error due to request %s with response status code %d", url, res.StatusCode)
-	}
+	defer body.Close()
 
-	if res.Header.Get("Content-Encoding") == "gzip" {
-		return gzip.NewReader(res.Body)
-	}
+	return ParseIndex(body, consumer)
+}
 
-	return res.Body, nil
+// defaultFetcher is the Fetcher used by ParseFromSite and
+// ParseIndexFromSite. It has no cache and never retries, matching the
+// historical behavior of those functions.
+var defaultFetcher = &Fetcher{}
+
+func get(ctx context.Context, url string) (io.ReadCloser, error) {
+	body, err := defaultFetcher.Fetch(ctx, url)
+	if errors.Is(err, ErrNotModified) {
+		// defaultFetcher never caches, so this can't actually happen,
+		// but handle it rather than leak a nil body.
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	return body, err
 }
@@ -2,14 +2,20 @@ package sitemap
 
 import "time"
 
+// sitemapEntry's extension fields are tagged with their full namespace
+// URI ("uri local-name" form) rather than a "prefix:local-name" literal,
+// since encoding/xml matches decoded elements by resolved namespace and
+// local name, not by the source document's chosen prefix.
 type sitemapEntry struct {
 	Location           string `xml:"loc"`
-	LastModified       string `xml:"lastmod,omitempy"`
+	LastModified       string `xml:"lastmod,omitempty"`
 	ParsedLastModified *time.Time
-	ChangeFrequency    Frequency `xml:"changefreq,omitempty"`
-	Priority           float32   `xml:"priority,omitempty"`
-	Images             []Image   `xml:"image,omitempty"`
-	News               *News     `xml:"news,omitempty"`
+	ChangeFrequency    Frequency       `xml:"changefreq,omitempty"`
+	Priority           float32         `xml:"priority,omitempty"`
+	Images             []Image         `xml:"http://www.google.com/schemas/sitemap-image/1.1 image,omitempty"`
+	News               *News           `xml:"http://www.google.com/schemas/sitemap-news/0.9 news,omitempty"`
+	Videos             []Video         `xml:"http://www.google.com/schemas/sitemap-video/1.1 video,omitempty"`
+	AlternateLinks     []alternateLink `xml:"http://www.w3.org/1999/xhtml link,omitempty"`
 }
 
 type Image struct {
@@ -19,13 +25,49 @@ type Image struct {
 
 type News struct {
 	Publication struct {
-		Name     string `xml:"name,omitempy"`
-		Language string `xml:"language,omitempy"`
-	} `xml:"publication,omitempy"`
-	PublicationDate       string `xml:"publication_date,omitempy"`
+		Name     string `xml:"name,omitempty"`
+		Language string `xml:"language,omitempty"`
+	} `xml:"publication,omitempty"`
+	PublicationDate       string `xml:"publication_date,omitempty"`
+	ParsedPublicationDate *time.Time
+	Title                 string `xml:"title,omitempty"`
+	Keywords              string `xml:"keywords,omitempty"`
+	Genres                string `xml:"genres,omitempty"`
+	StockTickers          string `xml:"stock_tickers,omitempty"`
+}
+
+// Video describes a video:video entry attached to a sitemap URL, per
+// Google's video sitemap extension.
+type Video struct {
+	ThumbnailLocation     string `xml:"thumbnail_loc"`
+	Title                 string `xml:"title"`
+	Description           string `xml:"description"`
+	ContentLocation       string `xml:"content_loc,omitempty"`
+	PlayerLocation        string `xml:"player_loc,omitempty"`
+	Duration              int    `xml:"duration,omitempty"`
+	PublicationDate       string `xml:"publication_date,omitempty"`
 	ParsedPublicationDate *time.Time
-	Title                 string `xml:"title,omitempy"`
-	Keywords              string `xml:"keywords,omitempy"`
+	FamilyFriendly        string   `xml:"family_friendly,omitempty"`
+	Tags                  []string `xml:"tag,omitempty"`
+	Category              string   `xml:"category,omitempty"`
+	Restriction           string   `xml:"restriction,omitempty"`
+}
+
+// GetPublicationDate parses and returns the video's publication date.
+// Be careful. Each call return new time.Time instance.
+func (v *Video) GetPublicationDate() *time.Time {
+	if v.ParsedPublicationDate == nil && v.PublicationDate != "" {
+		v.ParsedPublicationDate = parseDateTime(v.PublicationDate)
+	}
+	return v.ParsedPublicationDate
+}
+
+// alternateLink is the XML shape of an xhtml:link rel="alternate" hreflang
+// annotation attached to a sitemap URL.
+type alternateLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
 }
 
 func newSitemapEntry() *sitemapEntry {
@@ -59,6 +101,30 @@ func (e *sitemapEntry) GetNews() *News {
 	return e.News
 }
 
+func (e *sitemapEntry) GetVideos() []Video {
+	return e.Videos
+}
+
+// GetAlternates returns the entry's hreflang alternates, keyed by hreflang
+// code. It returns nil if the entry declares none.
+func (e *sitemapEntry) GetAlternates() map[string]string {
+	if len(e.AlternateLinks) == 0 {
+		return nil
+	}
+
+	alternates := make(map[string]string, len(e.AlternateLinks))
+	for _, link := range e.AlternateLinks {
+		if link.Rel != "alternate" || link.Hreflang == "" {
+			continue
+		}
+		alternates[link.Hreflang] = link.Href
+	}
+	if len(alternates) == 0 {
+		return nil
+	}
+	return alternates
+}
+
 func (n *News) GetPublicationDate() *time.Time {
 	if n.ParsedPublicationDate == nil && n.PublicationDate != "" {
 		n.ParsedPublicationDate = parseDateTime(n.PublicationDate)
@@ -0,0 +1,340 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WalkOptions configures Walk.
+type WalkOptions struct {
+	// From and To restrict which entries, and which entire sub-sitemaps
+	// when an index provides a lastmod, are passed to the consumer. A
+	// zero value disables the corresponding bound.
+	From time.Time
+	To   time.Time
+
+	// MaxDepth limits how many sitemapindex levels Walk will descend
+	// into. Zero means unlimited.
+	MaxDepth int
+
+	// Concurrency is the number of sitemaps fetched in parallel. Values
+	// <= 0 are treated as 1.
+	Concurrency int
+
+	// MaxErrors is the number of fetch/parse errors Walk tolerates
+	// before aborting the walk. Zero means abort on the first error.
+	MaxErrors int
+
+	// Fetcher downloads each sitemap and robots.txt, honoring its Cache
+	// so walkers that re-poll large sitemap indexes avoid redundant
+	// downloads. A nil Fetcher uses a plain, cache-less Fetcher.
+	Fetcher *Fetcher
+}
+
+// Walk discovers sitemaps reachable from roots, recursively descends any
+// sitemapindex files it finds, and streams every leaf Entry to consumer.
+//
+// Each root is either a direct sitemap URL (".xml" or ".xml.gz") or a
+// site root, in which case Walk requests "<root>/robots.txt" and follows
+// its "Sitemap:" directives. Sitemap URLs are deduplicated, so a URL
+// reachable through more than one path is only fetched once.
+//
+// consumer is always invoked from a single goroutine, even though up to
+// opts.Concurrency sitemaps are being fetched and parsed concurrently, so
+// consumer implementations don't need their own locking.
+func Walk(ctx context.Context, roots []string, opts WalkOptions, consumer EntryConsumer) error {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	fetcher := opts.Fetcher
+	if fetcher == nil {
+		fetcher = &Fetcher{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := &walker{
+		opts:     opts,
+		fetcher:  fetcher,
+		consumer: consumer,
+		seen:     make(map[string]bool),
+		sem:      make(chan struct{}, opts.Concurrency),
+		entries:  make(chan Entry),
+		cancel:   cancel,
+	}
+
+	var starts []string
+	for _, root := range roots {
+		if isSitemapURL(root) {
+			starts = append(starts, root)
+			continue
+		}
+
+		found, err := discoverSitemaps(ctx, fetcher, root)
+		if err != nil {
+			w.recordErr(fmt.Errorf("sitemap: discovering sitemaps for %s: %w", root, err))
+			continue
+		}
+		starts = append(starts, found...)
+	}
+
+	return w.walk(ctx, starts)
+}
+
+// discoverSitemaps fetches "<root>/robots.txt" and returns every URL
+// named by a "Sitemap:" directive.
+func discoverSitemaps(ctx context.Context, fetcher *Fetcher, root string) ([]string, error) {
+	robotsURL := strings.TrimRight(root, "/") + "/robots.txt"
+
+	body, err := fetcher.Fetch(ctx, robotsURL)
+	if errors.Is(err, ErrNotModified) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var sitemaps []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		rest, ok := cutPrefixFold(strings.TrimSpace(scanner.Text()), "sitemap:")
+		if !ok {
+			continue
+		}
+		sitemaps = append(sitemaps, strings.TrimSpace(rest))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(sitemaps) == 0 {
+		return nil, fmt.Errorf("sitemap: no Sitemap directives found in %s", robotsURL)
+	}
+
+	return sitemaps, nil
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return "", false
+	}
+	return s[len(prefix):], true
+}
+
+func isSitemapURL(url string) bool {
+	return strings.HasSuffix(url, ".xml") || strings.HasSuffix(url, ".xml.gz")
+}
+
+type walkJob struct {
+	url   string
+	depth int
+}
+
+// walker holds the state shared by a single Walk call.
+type walker struct {
+	opts     WalkOptions
+	fetcher  *Fetcher
+	consumer EntryConsumer
+
+	mu   sync.Mutex
+	seen map[string]bool
+
+	sem     chan struct{}
+	entries chan Entry
+
+	errCount int32
+	errMu    sync.Mutex
+	firstErr error
+
+	consumerErr error
+	cancel      context.CancelFunc
+}
+
+func (w *walker) walk(ctx context.Context, starts []string) error {
+	var consumerWG sync.WaitGroup
+	consumerWG.Add(1)
+	go func() {
+		defer consumerWG.Done()
+		for e := range w.entries {
+			if w.consumerErr != nil {
+				continue // keep draining so producers blocked on the channel don't deadlock
+			}
+			if err := w.consumer(e); err != nil {
+				w.consumerErr = err
+				w.cancel()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var process func(job walkJob)
+	process = func(job walkJob) {
+		defer wg.Done()
+		w.processOne(ctx, job, &wg, process)
+	}
+
+	for _, url := range starts {
+		wg.Add(1)
+		go process(walkJob{url: url})
+	}
+
+	wg.Wait()
+	close(w.entries)
+	consumerWG.Wait()
+
+	if w.consumerErr != nil {
+		return w.consumerErr
+	}
+	return w.firstErr
+}
+
+func (w *walker) processOne(ctx context.Context, job walkJob, wg *sync.WaitGroup, process func(walkJob)) {
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+
+	if w.opts.MaxDepth > 0 && job.depth > w.opts.MaxDepth {
+		return
+	}
+
+	w.mu.Lock()
+	if w.seen[job.url] {
+		w.mu.Unlock()
+		return
+	}
+	w.seen[job.url] = true
+	w.mu.Unlock()
+
+	w.sem <- struct{}{}
+	defer func() { <-w.sem }()
+
+	body, isIndex, err := fetchSitemap(ctx, w.fetcher, job.url)
+	if errors.Is(err, ErrNotModified) {
+		return
+	}
+	if err != nil {
+		w.recordErr(fmt.Errorf("sitemap: fetching %s: %w", job.url, err))
+		return
+	}
+	defer body.Close()
+
+	if isIndex {
+		err = ParseIndex(body, func(e IndexEntry) error {
+			if lastmod := e.GetLastModified(); lastmod != nil && !w.inRange(*lastmod) {
+				return nil
+			}
+			wg.Add(1)
+			go process(walkJob{url: e.GetLocation(), depth: job.depth + 1})
+			return nil
+		})
+	} else {
+		err = Parse(body, func(e Entry) error {
+			if lastmod := e.GetLastModified(); lastmod != nil && !w.inRange(*lastmod) {
+				return nil
+			}
+			select {
+			case w.entries <- e:
+			case <-ctx.Done():
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		w.recordErr(fmt.Errorf("sitemap: parsing %s: %w", job.url, err))
+	}
+}
+
+// recordErr records err as the walk's first error if none has been
+// recorded yet, and cancels the walk once more than opts.MaxErrors
+// errors have been observed.
+func (w *walker) recordErr(err error) {
+	w.errMu.Lock()
+	if w.firstErr == nil {
+		w.firstErr = err
+	}
+	w.errMu.Unlock()
+
+	if int(atomic.AddInt32(&w.errCount, 1)) > w.opts.MaxErrors {
+		w.cancel()
+	}
+}
+
+func (w *walker) inRange(t time.Time) bool {
+	if !w.opts.From.IsZero() && t.Before(w.opts.From) {
+		return false
+	}
+	if !w.opts.To.IsZero() && t.After(w.opts.To) {
+		return false
+	}
+	return true
+}
+
+// fetchSitemap fetches url using fetcher and reports whether its root
+// element is a sitemapindex (as opposed to a urlset), transparently
+// gunzipping ".xml.gz" URLs whose body wasn't already decompressed based
+// on a Content-Encoding header.
+func fetchSitemap(ctx context.Context, fetcher *Fetcher, url string) (io.ReadCloser, bool, error) {
+	body, err := fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if strings.HasSuffix(url, ".gz") {
+		body, err = maybeGunzip(body)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	br := bufio.NewReaderSize(body, 4096)
+	peek, _ := br.Peek(4096)
+	isIndex := bytes.Contains(peek, []byte("<sitemapindex"))
+
+	return readCloser{Reader: br, closer: body}, isIndex, nil
+}
+
+// maybeGunzip wraps rc in a gzip.Reader if its first two bytes are the
+// gzip magic number, and is a no-op otherwise. It guards against double
+// decompression when Fetcher.Fetch has already gunzipped a response based
+// on its Content-Encoding header.
+func maybeGunzip(rc io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+
+	magic, err := br.Peek(2)
+	if err != nil {
+		return readCloser{Reader: br, closer: rc}, nil
+	}
+
+	if magic[0] != 0x1f || magic[1] != 0x8b {
+		return readCloser{Reader: br, closer: rc}, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	return readCloser{Reader: gz, closer: rc}, nil
+}
+
+// readCloser pairs a Reader that wraps (and must not outlive) closer
+// with closer's Close method.
+type readCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (rc readCloser) Close() error { return rc.closer.Close() }
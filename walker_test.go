@@ -0,0 +1,304 @@
+package sitemap
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWalk_FollowsIndexAndRobots(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nSitemap: %s/sitemap-index.xml\n", serverURL(r))
+	})
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/sitemap-a.xml</loc></sitemap>
+</sitemapindex>`, serverURL(r))
+	})
+	mux.HandleFunc("/sitemap-a.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+  <url><loc>https://example.com/b</loc></url>
+</urlset>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var locations []string
+	err := Walk(context.Background(), []string{srv.URL}, WalkOptions{Concurrency: 2}, func(e Entry) error {
+		locations = append(locations, e.GetLocation())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %s", err)
+	}
+
+	if len(locations) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %v", len(locations), locations)
+	}
+}
+
+func TestWalk_DirectSitemapURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+</urlset>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var count int
+	err := Walk(context.Background(), []string{srv.URL + "/sitemap.xml"}, WalkOptions{}, func(e Entry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 entry, got %d", count)
+	}
+}
+
+func TestWalk_UsesFetcherCache(t *testing.T) {
+	var requests int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=3600")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+</urlset>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cache := newMemCache()
+	opts := WalkOptions{Fetcher: &Fetcher{Cache: cache}}
+
+	var count int
+	walk := func() {
+		err := Walk(context.Background(), []string{srv.URL + "/sitemap.xml"}, opts, func(e Entry) error {
+			count++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Walk failed: %s", err)
+		}
+	}
+
+	walk()
+	walk()
+
+	if requests != 1 {
+		t.Errorf("expected the second Walk to be served from cache, got %d requests", requests)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 entry across both walks, got %d", count)
+	}
+}
+
+func TestWalk_MaxDepth(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap-index-0.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/sitemap-index-1.xml</loc></sitemap>
+</sitemapindex>`, serverURL(r))
+	})
+	mux.HandleFunc("/sitemap-index-1.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/sitemap-leaf.xml</loc></sitemap>
+</sitemapindex>`, serverURL(r))
+	})
+	mux.HandleFunc("/sitemap-leaf.xml", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("sitemap-leaf.xml should not be reached past MaxDepth")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var count int
+	err := Walk(context.Background(), []string{srv.URL + "/sitemap-index-0.xml"}, WalkOptions{MaxDepth: 1}, func(e Entry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %s", err)
+	}
+	if count != 0 {
+		t.Errorf("expected sitemap-leaf.xml to be pruned past MaxDepth, got %d entries", count)
+	}
+}
+
+func TestWalk_MaxErrors_AbortsByDefault(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bad-a.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/bad-b.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	err := Walk(context.Background(), []string{srv.URL + "/bad-a.xml", srv.URL + "/bad-b.xml"}, WalkOptions{}, func(e Entry) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected an error when a sitemap fails to fetch")
+	}
+}
+
+func TestWalk_MaxErrors_ToleratesUpToLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bad-a.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/bad-b.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/good.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+</urlset>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var count int
+	err := Walk(context.Background(),
+		[]string{srv.URL + "/bad-a.xml", srv.URL + "/bad-b.xml", srv.URL + "/good.xml"},
+		WalkOptions{MaxErrors: 2, Concurrency: 1},
+		func(e Entry) error {
+			count++
+			return nil
+		})
+	if err == nil {
+		t.Fatal("expected Walk to still report the tolerated errors")
+	}
+	if count != 1 {
+		t.Errorf("expected the good sitemap to be processed despite 2 tolerated errors, got %d entries", count)
+	}
+}
+
+func TestWalk_ToleratesBadRootAlongsideGoodRoot(t *testing.T) {
+	badSrv := httptest.NewServer(http.NotFoundHandler())
+	defer badSrv.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nSitemap: %s/sitemap.xml\n", serverURL(r))
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+</urlset>`)
+	})
+	goodSrv := httptest.NewServer(mux)
+	defer goodSrv.Close()
+
+	var count int
+	err := Walk(context.Background(),
+		[]string{badSrv.URL, goodSrv.URL},
+		WalkOptions{MaxErrors: 5},
+		func(e Entry) error {
+			count++
+			return nil
+		})
+	if err == nil {
+		t.Fatal("expected Walk to still report the tolerated robots.txt discovery failure")
+	}
+	if count != 1 {
+		t.Errorf("expected the good root to still be walked despite the bad root, got %d entries", count)
+	}
+}
+
+func TestWalk_GzipSitemap(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		gz := gzip.NewWriter(w)
+		fmt.Fprint(gz, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+</urlset>`)
+		if err := gz.Close(); err != nil {
+			t.Fatalf("gzip.Close failed: %s", err)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var count int
+	err := Walk(context.Background(), []string{srv.URL + "/sitemap.xml.gz"}, WalkOptions{}, func(e Entry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 entry, got %d", count)
+	}
+}
+
+func TestWalk_DateFiltering(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/sitemap-old.xml</loc><lastmod>2019-01-01</lastmod></sitemap>
+  <sitemap><loc>%[1]s/sitemap-new.xml</loc><lastmod>2024-01-01</lastmod></sitemap>
+</sitemapindex>`, serverURL(r))
+	})
+	mux.HandleFunc("/sitemap-old.xml", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("sitemap-old.xml should have been pruned by date filtering")
+	})
+	mux.HandleFunc("/sitemap-new.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/a</loc></url>
+</urlset>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	opts := WalkOptions{From: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	var count int
+	err := Walk(context.Background(), []string{srv.URL + "/sitemap-index.xml"}, opts, func(e Entry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 entry from the in-range sub-sitemap, got %d", count)
+	}
+}
+
+func serverURL(r *http.Request) string {
+	return "http://" + r.Host
+}
@@ -0,0 +1,386 @@
+package sitemap
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// The sitemap protocol limits a single sitemap file to 50,000 URLs and
+// 50MB (uncompressed). A sitemap index is required once those limits are
+// exceeded.
+const (
+	maxURLsPerFile  = 50000
+	maxBytesPerFile = 50 * 1024 * 1024
+)
+
+const (
+	xmlnsSitemap = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	xmlnsImage   = "http://www.google.com/schemas/sitemap-image/1.1"
+	xmlnsNews    = "http://www.google.com/schemas/sitemap-news/0.9"
+	xmlnsVideo   = "http://www.google.com/schemas/sitemap-video/1.1"
+	xmlnsXhtml   = "http://www.w3.org/1999/xhtml"
+)
+
+// Writer streams Entry values to an underlying io.Writer as valid sitemap
+// XML. It validates each entry before writing it and tracks the number of
+// URLs and bytes written so callers can split output across multiple files
+// once the protocol's 50,000 URL or 50MB limit is reached, see
+// NeedsRotation.
+//
+// A Writer is not safe for concurrent use.
+type Writer struct {
+	cw      *countingWriter
+	enc     *xml.Encoder
+	count   int
+	closed  bool
+	started bool
+}
+
+// NewWriter returns a Writer that writes a sitemap document to w. The
+// caller must call Close once done to emit the closing urlset tag.
+func NewWriter(w io.Writer) *Writer {
+	cw := &countingWriter{w: w}
+	return &Writer{cw: cw, enc: xml.NewEncoder(cw)}
+}
+
+// URLCount returns the number of entries written so far.
+func (w *Writer) URLCount() int {
+	return w.count
+}
+
+// BytesWritten returns the number of bytes written so far.
+func (w *Writer) BytesWritten() int64 {
+	return w.cw.n
+}
+
+// NeedsRotation reports whether the Writer has reached the sitemap
+// protocol's 50,000 URL or 50MB per-file limit and a new file should be
+// started before the next Add.
+func (w *Writer) NeedsRotation() bool {
+	return w.count >= maxURLsPerFile || w.cw.n >= maxBytesPerFile
+}
+
+// Add validates e and appends it to the sitemap. It returns an error
+// without writing anything if e.GetPriority, e.GetChangeFrequency or
+// e.GetLastModified hold an invalid value.
+func (w *Writer) Add(e Entry) error {
+	if w.closed {
+		return fmt.Errorf("sitemap: Add called after Close")
+	}
+
+	out, err := newOutputEntry(e)
+	if err != nil {
+		return err
+	}
+
+	if !w.started {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+		w.started = true
+	}
+
+	if err := w.enc.Encode(out); err != nil {
+		return err
+	}
+
+	w.count++
+	return nil
+}
+
+func (w *Writer) writeHeader() error {
+	_, err := io.WriteString(w.cw, xml.Header+
+		`<urlset xmlns="`+xmlnsSitemap+
+		`" xmlns:image="`+xmlnsImage+
+		`" xmlns:news="`+xmlnsNews+
+		`" xmlns:video="`+xmlnsVideo+
+		`" xmlns:xhtml="`+xmlnsXhtml+`">`+"\n")
+	return err
+}
+
+// Close flushes any pending XML tokens and writes the closing urlset tag.
+// Close must be called exactly once and no further calls to Add are
+// allowed afterwards.
+func (w *Writer) Close() error {
+	if w.closed {
+		return fmt.Errorf("sitemap: Close called twice")
+	}
+	w.closed = true
+
+	if !w.started {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w.cw, "</urlset>\n")
+	return err
+}
+
+// IndexWriter streams IndexEntry values to an underlying io.Writer as a
+// valid sitemap index document.
+//
+// An IndexWriter is not safe for concurrent use.
+type IndexWriter struct {
+	cw      *countingWriter
+	enc     *xml.Encoder
+	closed  bool
+	started bool
+}
+
+// NewIndexWriter returns an IndexWriter that writes a sitemap index
+// document to w. The caller must call Close once done to emit the
+// closing sitemapindex tag.
+func NewIndexWriter(w io.Writer) *IndexWriter {
+	cw := &countingWriter{w: w}
+	return &IndexWriter{cw: cw, enc: xml.NewEncoder(cw)}
+}
+
+// AddIndex appends e to the sitemap index.
+func (w *IndexWriter) AddIndex(e IndexEntry) error {
+	if w.closed {
+		return fmt.Errorf("sitemap: AddIndex called after Close")
+	}
+
+	if e.GetLocation() == "" {
+		return fmt.Errorf("sitemap: index entry location is required")
+	}
+
+	if !w.started {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+		w.started = true
+	}
+
+	out := outputIndexEntry{Location: e.GetLocation()}
+	if lastmod := e.GetLastModified(); lastmod != nil {
+		out.LastModified = lastmod.Format(time.RFC3339)
+	}
+
+	return w.enc.Encode(out)
+}
+
+func (w *IndexWriter) writeHeader() error {
+	_, err := io.WriteString(w.cw, xml.Header+`<sitemapindex xmlns="`+xmlnsSitemap+`">`+"\n")
+	return err
+}
+
+// Close flushes any pending XML tokens and writes the closing
+// sitemapindex tag. Close must be called exactly once.
+func (w *IndexWriter) Close() error {
+	if w.closed {
+		return fmt.Errorf("sitemap: Close called twice")
+	}
+	w.closed = true
+
+	if !w.started {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w.cw, "</sitemapindex>\n")
+	return err
+}
+
+// WriteToFile writes entries to a single sitemap file at path, creating
+// or truncating it as needed.
+func WriteToFile(path string, entries []Entry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return writeAll(f, entries)
+}
+
+// WriteGzipToFile writes entries to a single gzip-compressed sitemap file
+// at path (conventionally named with a .xml.gz suffix), creating or
+// truncating it as needed.
+func WriteGzipToFile(path string, entries []Entry) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+
+	return writeAll(gw, entries)
+}
+
+func writeAll(w io.Writer, entries []Entry) error {
+	sw := NewWriter(w)
+	for _, e := range entries {
+		if err := sw.Add(e); err != nil {
+			return err
+		}
+	}
+	return sw.Close()
+}
+
+// WriteSet writes entries across one or more sitemap files under dir,
+// named "<baseName>-<n>.xml", rotating to a new file whenever the
+// protocol's 50,000 URL / 50MB limit is reached, and writes a sitemap
+// index named "<baseName>-index.xml" referencing each part.
+func WriteSet(dir, baseName string, entries []Entry) error {
+	var parts []string
+
+	part := 1
+	w, f, err := createPart(dir, baseName, part)
+	if err != nil {
+		return err
+	}
+	parts = append(parts, f.Name())
+
+	for _, e := range entries {
+		if w.NeedsRotation() {
+			if err := w.Close(); err != nil {
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+
+			part++
+			w, f, err = createPart(dir, baseName, part)
+			if err != nil {
+				return err
+			}
+			parts = append(parts, f.Name())
+		}
+
+		if err := w.Add(e); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	indexPath := fmt.Sprintf("%s/%s-index.xml", dir, baseName)
+	idxFile, err := os.Create(indexPath)
+	if err != nil {
+		return err
+	}
+	defer idxFile.Close()
+
+	idx := NewIndexWriter(idxFile)
+	for _, p := range parts {
+		if err := idx.AddIndex(plainIndexEntry{location: p}); err != nil {
+			return err
+		}
+	}
+	return idx.Close()
+}
+
+func createPart(dir, baseName string, part int) (*Writer, *os.File, error) {
+	path := fmt.Sprintf("%s/%s-%d.xml", dir, baseName, part)
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewWriter(f), f, nil
+}
+
+// plainIndexEntry is a minimal IndexEntry implementation used internally
+// to feed WriteSet's generated file names into an IndexWriter.
+type plainIndexEntry struct {
+	location string
+}
+
+func (e plainIndexEntry) GetLocation() string         { return e.location }
+func (e plainIndexEntry) GetLastModified() *time.Time { return nil }
+
+// outputEntry is the XML shape written for a single Entry by Writer. It
+// is kept separate from sitemapEntry because the latter carries
+// parsing-only bookkeeping fields.
+type outputEntry struct {
+	XMLName         xml.Name        `xml:"url"`
+	Location        string          `xml:"loc"`
+	LastModified    string          `xml:"lastmod,omitempty"`
+	ChangeFrequency Frequency       `xml:"changefreq,omitempty"`
+	Priority        float32         `xml:"priority"`
+	Images          []Image         `xml:"image:image,omitempty"`
+	News            *News           `xml:"news:news,omitempty"`
+	Videos          []Video         `xml:"video:video,omitempty"`
+	Alternates      []alternateLink `xml:"xhtml:link,omitempty"`
+}
+
+type outputIndexEntry struct {
+	XMLName      xml.Name `xml:"sitemap"`
+	Location     string   `xml:"loc"`
+	LastModified string   `xml:"lastmod,omitempty"`
+}
+
+func newOutputEntry(e Entry) (*outputEntry, error) {
+	if e.GetLocation() == "" {
+		return nil, fmt.Errorf("sitemap: entry location is required")
+	}
+
+	if p := e.GetPriority(); p < 0 || p > 1 {
+		return nil, fmt.Errorf("sitemap: priority %v out of range [0,1]", p)
+	}
+
+	switch e.GetChangeFrequency() {
+	case "", Always, Hourly, Daily, Weekly, Monthly, Yearly, Never:
+	default:
+		return nil, fmt.Errorf("sitemap: invalid change frequency %q", e.GetChangeFrequency())
+	}
+
+	out := &outputEntry{
+		Location:        e.GetLocation(),
+		ChangeFrequency: e.GetChangeFrequency(),
+		Priority:        e.GetPriority(),
+		Images:          e.GetImages(),
+		News:            e.GetNews(),
+		Videos:          e.GetVideos(),
+		Alternates:      alternatesToLinks(e.GetAlternates()),
+	}
+
+	if lastmod := e.GetLastModified(); lastmod != nil {
+		if y := lastmod.Year(); y < 0 || y > 9999 {
+			return nil, fmt.Errorf("sitemap: lastmod %v has a year outside [0,9999]", lastmod)
+		}
+		out.LastModified = lastmod.Format(time.RFC3339)
+	}
+
+	return out, nil
+}
+
+func alternatesToLinks(alternates map[string]string) []alternateLink {
+	if len(alternates) == 0 {
+		return nil
+	}
+
+	links := make([]alternateLink, 0, len(alternates))
+	for hreflang, href := range alternates {
+		links = append(links, alternateLink{Rel: "alternate", Hreflang: hreflang, Href: href})
+	}
+	return links
+}
+
+// countingWriter wraps an io.Writer and tracks the number of bytes
+// written through it, so Writer can detect the sitemap protocol's 50MB
+// per-file limit.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,275 @@
+package sitemap
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeEntry struct {
+	location   string
+	priority   float32
+	freq       Frequency
+	images     []Image
+	news       *News
+	videos     []Video
+	alternates map[string]string
+}
+
+func (e fakeEntry) GetLocation() string              { return e.location }
+func (e fakeEntry) GetLastModified() *time.Time      { return nil }
+func (e fakeEntry) GetChangeFrequency() Frequency    { return e.freq }
+func (e fakeEntry) GetPriority() float32             { return e.priority }
+func (e fakeEntry) GetImages() []Image               { return e.images }
+func (e fakeEntry) GetNews() *News                   { return e.news }
+func (e fakeEntry) GetVideos() []Video               { return e.videos }
+func (e fakeEntry) GetAlternates() map[string]string { return e.alternates }
+
+func TestWriter_RoundTrip(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb)
+
+	if err := w.Add(fakeEntry{location: "https://example.com/a", priority: 0.5, freq: Daily}); err != nil {
+		t.Fatalf("Add failed: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	var count int
+	err := Parse(strings.NewReader(sb.String()), func(e Entry) error {
+		count++
+		if e.GetLocation() != "https://example.com/a" {
+			t.Errorf("unexpected location %s", e.GetLocation())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("re-parsing written sitemap failed: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 entry, got %d", count)
+	}
+}
+
+func TestWriter_RoundTripsZeroPriority(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb)
+
+	if err := w.Add(fakeEntry{location: "https://example.com/a", priority: 0}); err != nil {
+		t.Fatalf("Add failed: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	var got float32 = -1
+	err := Parse(strings.NewReader(sb.String()), func(e Entry) error {
+		got = e.GetPriority()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("re-parsing written sitemap failed: %s", err)
+	}
+	if got != 0 {
+		t.Errorf("expected priority 0 to round-trip as 0, got %v", got)
+	}
+}
+
+func TestWriter_NamespacesImageAndNewsElements(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb)
+
+	entry := fakeEntry{
+		location: "https://example.com/a",
+		priority: 0.5,
+		images:   []Image{{ImageLocation: "https://example.com/a.jpg"}},
+		news:     &News{Title: "Breaking news"},
+	}
+	if err := w.Add(entry); err != nil {
+		t.Fatalf("Add failed: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	out := sb.String()
+	if !strings.Contains(out, "<image:image>") {
+		t.Errorf("expected output to contain a namespaced <image:image> element, got:\n%s", out)
+	}
+	if !strings.Contains(out, "<news:news>") {
+		t.Errorf("expected output to contain a namespaced <news:news> element, got:\n%s", out)
+	}
+}
+
+func TestWriter_NamespacesVideoAndAlternateElements(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb)
+
+	entry := fakeEntry{
+		location:   "https://example.com/a",
+		priority:   0.5,
+		videos:     []Video{{Title: "A video"}},
+		alternates: map[string]string{"de": "https://example.com/de"},
+	}
+	if err := w.Add(entry); err != nil {
+		t.Fatalf("Add failed: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	out := sb.String()
+	if !strings.Contains(out, "<video:video>") {
+		t.Errorf("expected output to contain a namespaced <video:video> element, got:\n%s", out)
+	}
+	if !strings.Contains(out, "<xhtml:link ") {
+		t.Errorf("expected output to contain a namespaced <xhtml:link> element, got:\n%s", out)
+	}
+}
+
+func TestWriter_RejectsInvalidPriority(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb)
+
+	err := w.Add(fakeEntry{location: "https://example.com/a", priority: 1.5})
+	if err == nil {
+		t.Error("expected error for out-of-range priority")
+	}
+}
+
+func TestWriter_NeedsRotation(t *testing.T) {
+	w := NewWriter(io.Discard)
+	for i := 0; i < maxURLsPerFile; i++ {
+		if w.NeedsRotation() {
+			t.Fatalf("NeedsRotation reported true after only %d of %d URLs", i, maxURLsPerFile)
+		}
+		if err := w.Add(fakeEntry{location: "https://example.com/", priority: 0.5}); err != nil {
+			t.Fatalf("Add failed: %s", err)
+		}
+	}
+	if !w.NeedsRotation() {
+		t.Errorf("expected NeedsRotation to report true after %d URLs", maxURLsPerFile)
+	}
+}
+
+func TestWriteToFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sitemap.xml")
+	entries := []Entry{
+		fakeEntry{location: "https://example.com/a", priority: 0.5},
+		fakeEntry{location: "https://example.com/b", priority: 0.5},
+	}
+	if err := WriteToFile(path, entries); err != nil {
+		t.Fatalf("WriteToFile failed: %s", err)
+	}
+
+	var count int
+	err := ParseFromFile(path, func(e Entry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("re-parsing written sitemap failed: %s", err)
+	}
+	if count != len(entries) {
+		t.Errorf("expected %d entries, got %d", len(entries), count)
+	}
+}
+
+func TestWriteGzipToFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sitemap.xml.gz")
+	entries := []Entry{fakeEntry{location: "https://example.com/a", priority: 0.5}}
+	if err := WriteGzipToFile(path, entries); err != nil {
+		t.Fatalf("WriteGzipToFile failed: %s", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening written file failed: %s", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader failed: %s", err)
+	}
+	defer gz.Close()
+
+	var count int
+	err = Parse(gz, func(e Entry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("re-parsing written sitemap failed: %s", err)
+	}
+	if count != len(entries) {
+		t.Errorf("expected %d entries, got %d", len(entries), count)
+	}
+}
+
+func TestWriteSet_RotatesAndWritesIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	entries := make([]Entry, maxURLsPerFile+1)
+	for i := range entries {
+		entries[i] = fakeEntry{location: "https://example.com/", priority: 0.5}
+	}
+
+	if err := WriteSet(dir, "sitemap", entries); err != nil {
+		t.Fatalf("WriteSet failed: %s", err)
+	}
+
+	var parts []string
+	err := ParseIndexFromFile(filepath.Join(dir, "sitemap-index.xml"), func(e IndexEntry) error {
+		parts = append(parts, e.GetLocation())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("parsing sitemap index failed: %s", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts due to the URL-count rotation, got %d: %v", len(parts), parts)
+	}
+
+	var total int
+	for _, p := range parts {
+		if err := ParseFromFile(p, func(e Entry) error {
+			total++
+			return nil
+		}); err != nil {
+			t.Fatalf("parsing part %s failed: %s", p, err)
+		}
+	}
+	if total != len(entries) {
+		t.Errorf("expected %d entries across parts, got %d", len(entries), total)
+	}
+}
+
+func TestIndexWriter_RoundTrip(t *testing.T) {
+	var sb strings.Builder
+	w := NewIndexWriter(&sb)
+
+	if err := w.AddIndex(plainIndexEntry{location: "https://example.com/sitemap-1.xml"}); err != nil {
+		t.Fatalf("AddIndex failed: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	var count int
+	err := ParseIndex(strings.NewReader(sb.String()), func(e IndexEntry) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("re-parsing written sitemap index failed: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 entry, got %d", count)
+	}
+}